@@ -4,7 +4,6 @@ package main
 import (
 	"bytes"
 	"context"
-	"encoding/binary"
 	"log"
 	"net"
 	"sync"
@@ -12,7 +11,9 @@ import (
 
 	"github.com/google/gopacket"
 	"github.com/google/gopacket/layers"
-	"github.com/google/gopacket/pcap"
+	"github.com/google/gopacket/routing"
+
+	"github.com/x1unix/arpscan/nicinfo"
 )
 
 const (
@@ -20,46 +21,120 @@ const (
 	arpPingInterval = 5 * time.Second
 )
 
-func scanIface(ctx context.Context, wg *sync.WaitGroup, iface net.Interface) {
+// scanConfig bundles the options scanIface needs, threaded down from the CLI
+// flags in main.go.
+type scanConfig struct {
+	Ports            []uint16
+	Reporter         Reporter
+	LostAfterCycles  int
+	HandleFactory    handleFactory
+	PacketsPerSecond int
+	Targets          []*net.IPNet
+
+	// Offline is set when replaying a saved capture (-read) rather than
+	// scanning a real NIC: iface is a synthetic placeholder with no OS-level
+	// address to look up, and there's nothing to send ARP requests onto, so
+	// scanIface skips both.
+	Offline bool
+}
+
+func scanIface(ctx context.Context, wg *sync.WaitGroup, iface net.Interface, cfg scanConfig) {
 	defer wg.Done()
 
-	// We just look for IPv4 addresses, so try to find if the interface has one.
+	reporter := cfg.Reporter
+
+	// We just look for IPv4 addresses, so try to find if the interface has
+	// one. A synthetic replay interface has no real OS address to look up.
 	addr := new(net.IPNet)
-	addrs, err := iface.Addrs()
-	if err != nil {
-		log.Printf("ERROR: %s - failed to get NIC addrs: %s\n", iface.Name, err)
-		return
-	}
+	if !cfg.Offline {
+		addrs, err := iface.Addrs()
+		if err != nil {
+			log.Printf("ERROR: %s - failed to get NIC addrs: %s\n", iface.Name, err)
+			return
+		}
 
-	// skip iface without IP
-	if len(addrs) == 0 {
-		return
-	}
+		// skip iface without IP
+		if len(addrs) == 0 {
+			return
+		}
 
-	for _, a := range addrs {
-		if ipnet, ok := a.(*net.IPNet); ok {
-			if ip4 := ipnet.IP.To4(); ip4 != nil {
-				addr = &net.IPNet{
-					IP:   ip4,
-					Mask: ipnet.Mask[len(ipnet.Mask)-4:],
+		for _, a := range addrs {
+			if ipnet, ok := a.(*net.IPNet); ok {
+				if ip4 := ipnet.IP.To4(); ip4 != nil {
+					addr = &net.IPNet{
+						IP:   ip4,
+						Mask: ipnet.Mask[len(ipnet.Mask)-4:],
+					}
+					break
 				}
-				break
 			}
 		}
 	}
 
-	// Open up a pcap handle for packet reads/writes.
-	handle, err := pcap.OpenLive(iface.Name, 65536, true, pcap.BlockForever)
+	// Open up a packet handle for reads/writes via the configured factory
+	// (live capture, offline replay, or a tee of either to a pcap file).
+	handle, err := cfg.HandleFactory(iface)
 	if err != nil {
 		log.Printf("ERROR: failed to open pcap interface: %s\n", err)
 		return
 	}
 	defer handle.Close()
 
-	// Start up a goroutine to read in packet data.
+	// Start up a single dispatcher goroutine to read packets off the handle
+	// and fan decoded ARP/TCP layers out to whichever of the logic below
+	// cares about them; nothing else is allowed to read from handle directly.
 	log.Println(":: scanning interface -", iface.Name)
-	//wg.Add(1)
-	go readARP(ctx, wg, handle, &iface)
+
+	dispatcher := newPacketDispatcher(handle)
+	wg.Add(1)
+	go dispatcher.run(ctx, wg)
+
+	var discovered chan discoveredHost
+	if len(cfg.Ports) > 0 {
+		// Feed hosts discovered via ARP into the SYN scanner below.
+		discovered = make(chan discoveredHost, 16)
+	}
+
+	cache := NewHostCache(cfg.LostAfterCycles)
+
+	dispatcher.OnARP(func(arp *layers.ARP) {
+		handleARPReply(arp, &iface, discovered, reporter, cache)
+	})
+
+	if discovered != nil {
+		router, err := routing.New()
+		if err != nil {
+			log.Printf("ERROR: %s - failed to build routing table for SYN scan: %s\n", iface.Name, err)
+		} else {
+			scanner := newSynScanner(handle, dispatcher, router)
+			wg.Add(1)
+			go scanner.ReadReplies(ctx, wg, func(ip net.IP, port uint16, state PortState) {
+				log.Printf(":: [%s] %s:%d %s", iface.Name, ip, port, state)
+			})
+			go func() {
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case host, ok := <-discovered:
+						if !ok {
+							return
+						}
+						if err := scanner.ScanHost(ctx, host.IP, host.MAC, cfg.Ports); err != nil {
+							log.Printf("ERROR: %s - SYN scan of %s failed: %s\n", iface.Name, host.IP, err)
+						}
+					}
+				}
+			}()
+		}
+	}
+
+	targets := cfg.Targets
+	if len(targets) == 0 && !cfg.Offline {
+		targets = []*net.IPNet{addr}
+	}
+	limiter := newTokenBucket(cfg.PacketsPerSecond)
+	defer limiter.Stop()
 
 	ticker := time.NewTicker(arpPingInterval)
 	for {
@@ -69,52 +144,69 @@ func scanIface(ctx context.Context, wg *sync.WaitGroup, iface net.Interface) {
 			log.Printf(":: [%s] Stop scanner", iface.Name)
 			return
 		case <-ticker.C:
-			// Write our scan packets out to the handle.
-			if err := writeARP(ctx, handle, &iface, addr); err != nil {
-				log.Printf("ERROR: %s - error writing packets: %v\n", iface.Name, err)
-				return
+			if !cfg.Offline {
+				// Write our scan packets out to the handle.
+				if err := writeARP(ctx, handle, &iface, addr, targets, limiter, reporter); err != nil {
+					log.Printf("ERROR: %s - error writing packets: %v\n", iface.Name, err)
+					return
+				}
 			}
+
+			now := time.Now()
+			for _, lost := range cache.Cycle() {
+				reporter.HostLost(iface.Name, lost.IP, lost.MAC, now)
+			}
+			reporter.ScanCycleComplete(iface.Name, now, cache.Count())
 		}
 	}
 }
 
-// readARP watches a handle for incoming ARP responses we might care about, and prints them.
-//
-// readARP loops until 'stop' is closed.
-func readARP(ctx context.Context, wg *sync.WaitGroup, handle *pcap.Handle, iface *net.Interface) {
-	src := gopacket.NewPacketSource(handle, layers.LayerTypeEthernet)
-	in := src.Packets()
-	defer wg.Done()
+// discoveredHost is an ARP-discovered host fed to the SYN scanner, carrying
+// along the MAC address the ARP reply already gave us so probes can be
+// addressed directly instead of broadcast.
+type discoveredHost struct {
+	IP  net.IP
+	MAC net.HardwareAddr
+}
 
-	var packet gopacket.Packet
-	for {
+// handleARPReply processes a single ARP packet seen by the interface's
+// packetDispatcher, reporting it via reporter if it's worth surfacing and,
+// if discovered is non-nil, queuing it for the SYN scanner.
+func handleARPReply(arp *layers.ARP, iface *net.Interface, discovered chan<- discoveredHost, reporter Reporter, cache *HostCache) {
+	if arp.Operation != layers.ARPReply || bytes.Equal(iface.HardwareAddr, arp.SourceHwAddress) {
+		// This is a packet I sent.
+		return
+	}
+	// Note:  we might get some packets here that aren't responses to ones we've sent,
+	// if for example someone else sends US an ARP request.  Doesn't much matter, though...
+	// all information is good information :)
+	hostIP := net.IP(arp.SourceProtAddress)
+	hostMAC := net.HardwareAddr(arp.SourceHwAddress)
+
+	reporter.ARPReplyReceived(iface.Name)
+	if cache.Observe(hostIP, hostMAC) {
+		reporter.HostDiscovered(iface.Name, hostIP, hostMAC, time.Now())
+	}
+
+	if discovered != nil {
 		select {
-		case <-ctx.Done():
-			log.Printf(":: [%s] Stop reader", iface.Name)
-			return
-		case packet = <-in:
-			arpLayer := packet.Layer(layers.LayerTypeARP)
-			if arpLayer == nil {
-				continue
-			}
-			arp := arpLayer.(*layers.ARP)
-			if arp.Operation != layers.ARPReply || bytes.Equal(iface.HardwareAddr, arp.SourceHwAddress) {
-				// This is a packet I sent.
-				continue
-			}
-			// Note:  we might get some packets here that aren't responses to ones we've sent,
-			// if for example someone else sends US an ARP request.  Doesn't much matter, though...
-			// all information is good information :)
-			log.Printf(":: [%s] Found %v (%v)", iface.Name,
-				net.IP(arp.SourceProtAddress),
-				net.HardwareAddr(arp.SourceHwAddress))
+		case discovered <- discoveredHost{IP: hostIP, MAC: hostMAC}:
+		default:
+			// SYN scanner is still busy with a previous host; drop rather than block reads.
 		}
 	}
 }
 
-// writeARP writes an ARP request for each address on our local network to the
-// pcap handle.
-func writeARP(ctx context.Context, handle *pcap.Handle, iface *net.Interface, addr *net.IPNet) error {
+// writeARP writes an ARP request for every host address across targets to
+// the pcap handle, pacing sends through limiter and jittering the start of
+// the burst so multiple interfaces scanned in parallel don't synchronize.
+func writeARP(ctx context.Context, handle PacketHandle, iface *net.Interface, addr *net.IPNet, targets []*net.IPNet, limiter *tokenBucket, reporter Reporter) error {
+	select {
+	case <-time.After(jitter(arpPingInterval / 4)):
+	case <-ctx.Done():
+		return context.Canceled
+	}
+
 	// Set up all the layers' fields we can.
 	eth := layers.Ethernet{
 		SrcMAC:       iface.HardwareAddr,
@@ -137,45 +229,30 @@ func writeARP(ctx context.Context, handle *pcap.Handle, iface *net.Interface, ad
 		FixLengths:       true,
 		ComputeChecksums: true,
 	}
-	// Send one packet for every address.
-	for _, ip := range ips(addr) {
-		select {
-		case <-ctx.Done():
-			log.Printf(":: [%s] Stop writer", iface.Name)
-			return context.Canceled
-		default:
-		}
 
-		//log.Printf(":: [%s] Write %s", iface.Name, ip.String())
-		arp.DstProtAddress = ip
-		if err := gopacket.SerializeLayers(buf, opts, &eth, &arp); err != nil {
-			log.Printf(":: WARN - %s\n", err)
-		}
-		if err := handle.WritePacketData(buf.Bytes()); err != nil {
-			return err
+	for _, target := range targets {
+		for ip := range hostAddrs(ctx, target) {
+			if err := limiter.Wait(ctx); err != nil {
+				log.Printf(":: [%s] Stop writer", iface.Name)
+				return context.Canceled
+			}
+
+			//log.Printf(":: [%s] Write %s", iface.Name, ip.String())
+			arp.DstProtAddress = ip
+			if err := gopacket.SerializeLayers(buf, opts, &eth, &arp); err != nil {
+				log.Printf(":: WARN - %s\n", err)
+				continue
+			}
+			if err := handle.WritePacketData(buf.Bytes()); err != nil {
+				return err
+			}
+			reporter.ARPRequestSent(iface.Name)
 		}
 	}
 	return nil
 }
 
-// ips is a simple and not very good method for getting all IPv4 addresses from a
-// net.IPNet.  It returns all IPs it can over the channel it sends back, closing
-// the channel when done.
-func ips(n *net.IPNet) (out []net.IP) {
-	num := binary.BigEndian.Uint32([]byte(n.IP))
-	mask := binary.BigEndian.Uint32([]byte(n.Mask))
-	num &= mask
-	for mask < 0xffffffff {
-		var buf [4]byte
-		binary.BigEndian.PutUint32(buf[:], num)
-		out = append(out, net.IP(buf[:]))
-		mask++
-		num++
-	}
-	return
-}
-
-func getInterfaces() ([]net.Interface, error) {
+func getInterfaces(includeVirtual bool) ([]net.Interface, error) {
 	allIfaces, err := net.Interfaces()
 	if err != nil {
 		return nil, err
@@ -190,8 +267,11 @@ func getInterfaces() ([]net.Interface, error) {
 			continue
 		}
 
-		if !isPhysicalNIC(iface) {
-			continue
+		if !includeVirtual {
+			info, err := nicinfo.Get(iface)
+			if err != nil || !info.IsPhysical {
+				continue
+			}
 		}
 		ifaces = append(ifaces, iface)
 	}
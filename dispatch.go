@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+// packetDispatcher reads every packet off a PacketHandle exactly once and
+// fans decoded ARP and TCP/IPv4 layers out to whichever subscribers are
+// currently interested. gopacket's PacketSource.Packets() has exactly one
+// reader per source, so readARP, the SYN scanner and gateway-MAC resolution
+// must not each open their own source on the same handle - they'd race each
+// other for packets and silently steal replies meant for one another.
+type packetDispatcher struct {
+	handle PacketHandle
+
+	mu      sync.Mutex
+	nextID  int
+	arpSubs map[int]func(*layers.ARP)
+	tcpSubs map[int]func(*layers.IPv4, *layers.TCP)
+}
+
+func newPacketDispatcher(handle PacketHandle) *packetDispatcher {
+	return &packetDispatcher{
+		handle:  handle,
+		arpSubs: make(map[int]func(*layers.ARP)),
+		tcpSubs: make(map[int]func(*layers.IPv4, *layers.TCP)),
+	}
+}
+
+// OnARP registers fn to be called with every ARP packet read off the handle.
+// The returned func unsubscribes it; callers that only need one reply (e.g.
+// gateway-MAC resolution) must call it once they're done.
+func (d *packetDispatcher) OnARP(fn func(*layers.ARP)) (unsubscribe func()) {
+	d.mu.Lock()
+	id := d.nextID
+	d.nextID++
+	d.arpSubs[id] = fn
+	d.mu.Unlock()
+
+	return func() {
+		d.mu.Lock()
+		delete(d.arpSubs, id)
+		d.mu.Unlock()
+	}
+}
+
+// OnTCP registers fn to be called with every IPv4/TCP packet read off the
+// handle. The returned func unsubscribes it.
+func (d *packetDispatcher) OnTCP(fn func(*layers.IPv4, *layers.TCP)) (unsubscribe func()) {
+	d.mu.Lock()
+	id := d.nextID
+	d.nextID++
+	d.tcpSubs[id] = fn
+	d.mu.Unlock()
+
+	return func() {
+		d.mu.Lock()
+		delete(d.tcpSubs, id)
+		d.mu.Unlock()
+	}
+}
+
+// run is the single goroutine allowed to read packets off handle; it loops
+// until ctx is cancelled, dispatching each packet to the relevant
+// subscribers.
+func (d *packetDispatcher) run(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	src := gopacket.NewPacketSource(d.handle, layers.LayerTypeEthernet)
+	in := src.Packets()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case packet, ok := <-in:
+			if !ok {
+				return
+			}
+			if arpLayer := packet.Layer(layers.LayerTypeARP); arpLayer != nil {
+				d.dispatchARP(arpLayer.(*layers.ARP))
+			}
+			if tcpLayer := packet.Layer(layers.LayerTypeTCP); tcpLayer != nil {
+				if ipLayer := packet.Layer(layers.LayerTypeIPv4); ipLayer != nil {
+					d.dispatchTCP(ipLayer.(*layers.IPv4), tcpLayer.(*layers.TCP))
+				}
+			}
+		}
+	}
+}
+
+func (d *packetDispatcher) dispatchARP(arp *layers.ARP) {
+	d.mu.Lock()
+	subs := make([]func(*layers.ARP), 0, len(d.arpSubs))
+	for _, fn := range d.arpSubs {
+		subs = append(subs, fn)
+	}
+	d.mu.Unlock()
+
+	for _, fn := range subs {
+		fn(arp)
+	}
+}
+
+func (d *packetDispatcher) dispatchTCP(ip *layers.IPv4, tcp *layers.TCP) {
+	d.mu.Lock()
+	subs := make([]func(*layers.IPv4, *layers.TCP), 0, len(d.tcpSubs))
+	for _, fn := range d.tcpSubs {
+		subs = append(subs, fn)
+	}
+	d.mu.Unlock()
+
+	for _, fn := range subs {
+		fn(ip, tcp)
+	}
+}
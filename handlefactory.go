@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcap"
+	"github.com/google/gopacket/pcapgo"
+)
+
+const defaultSnapLen = 65536
+
+// PacketHandle is the subset of *pcap.Handle the scanner needs: reading and
+// writing raw packets. Abstracting it lets scanIface run unmodified against
+// a live NIC, a saved capture file, or a handle that additionally tees
+// everything it sees to a pcap file.
+//
+// Deliberately excludes SetBPFFilter: it applies to the whole handle, not a
+// single reader, and this handle is shared between ARP discovery and the SYN
+// scanner via packetDispatcher - installing a filter for one probe would
+// silently cut the other off. Classification happens purely in software
+// against synScanner.pending instead.
+type PacketHandle interface {
+	gopacket.PacketDataSource
+	WritePacketData(data []byte) error
+	LinkType() layers.LinkType
+	Close()
+}
+
+// handleFactory produces the PacketHandle scanIface should use for iface.
+type handleFactory func(iface net.Interface) (PacketHandle, error)
+
+// liveHandleFactory opens a live capture on each interface. When
+// timestampSource is non-empty, it's requested from the NIC via
+// NewInactiveHandle/SetTimestampSource instead of falling back to the
+// kernel's software clock; not every NIC supports every source.
+func liveHandleFactory(promisc bool, timestampSource string) handleFactory {
+	return func(iface net.Interface) (PacketHandle, error) {
+		inactive, err := pcap.NewInactiveHandle(iface.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create pcap handle: %w", err)
+		}
+		defer inactive.CleanUp()
+
+		if err := inactive.SetSnapLen(defaultSnapLen); err != nil {
+			return nil, err
+		}
+		if err := inactive.SetPromisc(promisc); err != nil {
+			return nil, err
+		}
+		if err := inactive.SetTimeout(pcap.BlockForever); err != nil {
+			return nil, err
+		}
+		if timestampSource != "" {
+			src, err := pcap.TimestampSourceFromString(timestampSource)
+			if err != nil {
+				return nil, fmt.Errorf("invalid timestamp source %q: %w", timestampSource, err)
+			}
+			if err := inactive.SetTimestampSource(src); err != nil {
+				return nil, fmt.Errorf("%s does not support timestamp source %q: %w", iface.Name, timestampSource, err)
+			}
+		}
+
+		handle, err := inactive.Activate()
+		if err != nil {
+			return nil, fmt.Errorf("failed to activate pcap handle: %w", err)
+		}
+		return handle, nil
+	}
+}
+
+// offlineHandleFactory replays a single saved capture file in place of a live
+// interface, for tests and forensic replay without root privileges.
+func offlineHandleFactory(path string) handleFactory {
+	return func(net.Interface) (PacketHandle, error) {
+		return pcap.OpenOffline(path)
+	}
+}
+
+// teeHandleFactory wraps another factory so every packet it sees, sent or
+// received, is also written to a pcap file derived from path. Each interface
+// gets its own file, named by inserting its name before path's extension
+// (e.g. "capture.pcap" -> "capture.eth0.pcap") - otherwise two interfaces
+// scanned concurrently would each os.Create the same path out from under
+// each other, corrupting both captures.
+func teeHandleFactory(inner handleFactory, path string) handleFactory {
+	return func(iface net.Interface) (PacketHandle, error) {
+		handle, err := inner(iface)
+		if err != nil {
+			return nil, err
+		}
+
+		out, err := os.Create(perIfacePath(path, iface.Name))
+		if err != nil {
+			handle.Close()
+			return nil, err
+		}
+
+		w := pcapgo.NewWriter(out)
+		if err := w.WriteFileHeader(defaultSnapLen, handle.LinkType()); err != nil {
+			out.Close()
+			handle.Close()
+			return nil, err
+		}
+
+		return &teeHandle{PacketHandle: handle, out: out, w: w}, nil
+	}
+}
+
+// perIfacePath inserts ifaceName before path's extension, e.g.
+// perIfacePath("capture.pcap", "eth0") returns "capture.eth0.pcap". If path
+// has no extension, ifaceName is simply appended after a dot.
+func perIfacePath(path, ifaceName string) string {
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	return fmt.Sprintf("%s.%s%s", base, ifaceName, ext)
+}
+
+// teeHandle decorates a PacketHandle, mirroring every packet read or written
+// through it into an underlying pcapgo.Writer.
+type teeHandle struct {
+	PacketHandle
+	out *os.File
+	w   *pcapgo.Writer
+	mu  sync.Mutex
+}
+
+func (t *teeHandle) WritePacketData(data []byte) error {
+	if err := t.PacketHandle.WritePacketData(data); err != nil {
+		return err
+	}
+	return t.writePacket(gopacket.CaptureInfo{
+		Timestamp:     time.Now(),
+		CaptureLength: len(data),
+		Length:        len(data),
+	}, data)
+}
+
+func (t *teeHandle) ReadPacketData() ([]byte, gopacket.CaptureInfo, error) {
+	data, ci, err := t.PacketHandle.ReadPacketData()
+	if err != nil {
+		return data, ci, err
+	}
+	if werr := t.writePacket(ci, data); werr != nil {
+		log.Printf(":: WARN - failed to write pcap tee: %s\n", werr)
+	}
+	return data, ci, err
+}
+
+func (t *teeHandle) writePacket(ci gopacket.CaptureInfo, data []byte) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.w.WritePacket(ci, data)
+}
+
+func (t *teeHandle) Close() {
+	t.PacketHandle.Close()
+	t.out.Close()
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcapgo"
+)
+
+func writeTestPcap(t *testing.T, path string, packets [][]byte) {
+	t.Helper()
+
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("create pcap: %s", err)
+	}
+	defer f.Close()
+
+	w := pcapgo.NewWriter(f)
+	if err := w.WriteFileHeader(defaultSnapLen, layers.LinkTypeEthernet); err != nil {
+		t.Fatalf("write pcap header: %s", err)
+	}
+	for _, data := range packets {
+		ci := gopacket.CaptureInfo{Timestamp: time.Now(), CaptureLength: len(data), Length: len(data)}
+		if err := w.WritePacket(ci, data); err != nil {
+			t.Fatalf("write packet: %s", err)
+		}
+	}
+}
+
+func arpReplyPacket(t *testing.T, srcMAC net.HardwareAddr, srcIP net.IP, dstMAC net.HardwareAddr, dstIP net.IP) []byte {
+	t.Helper()
+
+	eth := layers.Ethernet{SrcMAC: srcMAC, DstMAC: dstMAC, EthernetType: layers.EthernetTypeARP}
+	arp := layers.ARP{
+		AddrType:          layers.LinkTypeEthernet,
+		Protocol:          layers.EthernetTypeIPv4,
+		HwAddressSize:     6,
+		ProtAddressSize:   4,
+		Operation:         layers.ARPReply,
+		SourceHwAddress:   []byte(srcMAC),
+		SourceProtAddress: []byte(srcIP.To4()),
+		DstHwAddress:      []byte(dstMAC),
+		DstProtAddress:    []byte(dstIP.To4()),
+	}
+
+	buf := gopacket.NewSerializeBuffer()
+	opts := gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}
+	if err := gopacket.SerializeLayers(buf, opts, &eth, &arp); err != nil {
+		t.Fatalf("serialize ARP reply: %s", err)
+	}
+	return buf.Bytes()
+}
+
+// TestOfflineHandleFactory exercises the reason offlineHandleFactory exists:
+// feeding a canned capture through the scanner's ARP-discovery logic without
+// a live interface or root privileges.
+func TestOfflineHandleFactory(t *testing.T) {
+	localMAC := net.HardwareAddr{0x02, 0x00, 0x00, 0x00, 0x00, 0x01}
+	hostMAC := net.HardwareAddr{0x02, 0x00, 0x00, 0x00, 0x00, 0x02}
+	hostIP := net.IPv4(192, 168, 1, 50)
+
+	path := filepath.Join(t.TempDir(), "arp.pcap")
+	writeTestPcap(t, path, [][]byte{
+		arpReplyPacket(t, hostMAC, hostIP, localMAC, net.IPv4(192, 168, 1, 1)),
+	})
+
+	handle, err := offlineHandleFactory(path)(net.Interface{Name: "testIface", HardwareAddr: localMAC})
+	if err != nil {
+		t.Fatalf("offlineHandleFactory: %s", err)
+	}
+	defer handle.Close()
+
+	data, _, err := handle.ReadPacketData()
+	if err != nil {
+		t.Fatalf("ReadPacketData: %s", err)
+	}
+
+	packet := gopacket.NewPacket(data, layers.LayerTypeEthernet, gopacket.Default)
+	arpLayer := packet.Layer(layers.LayerTypeARP)
+	if arpLayer == nil {
+		t.Fatal("expected an ARP layer in the replayed packet")
+	}
+
+	iface := &net.Interface{Name: "testIface", HardwareAddr: localMAC}
+	cache := NewHostCache(3)
+	discovered := make(chan discoveredHost, 1)
+	handleARPReply(arpLayer.(*layers.ARP), iface, discovered, newLogReporter(), cache)
+
+	select {
+	case host := <-discovered:
+		if !host.IP.Equal(hostIP) {
+			t.Errorf("got IP %s, want %s", host.IP, hostIP)
+		}
+		if host.MAC.String() != hostMAC.String() {
+			t.Errorf("got MAC %s, want %s", host.MAC, hostMAC)
+		}
+	default:
+		t.Fatal("handleARPReply did not queue the discovered host")
+	}
+}
+
+// TestPerIfacePath covers teeHandleFactory's per-interface output naming.
+func TestPerIfacePath(t *testing.T) {
+	tests := []struct {
+		path, iface, want string
+	}{
+		{"capture.pcap", "eth0", "capture.eth0.pcap"},
+		{"capture", "eth0", "capture.eth0"},
+		{"/tmp/out.pcap", "wlan0", "/tmp/out.wlan0.pcap"},
+	}
+
+	for _, tt := range tests {
+		if got := perIfacePath(tt.path, tt.iface); got != tt.want {
+			t.Errorf("perIfacePath(%q, %q) = %q, want %q", tt.path, tt.iface, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"net"
+	"sync"
+)
+
+// cachedHost tracks the last known state of a single discovered host.
+type cachedHost struct {
+	mac           net.HardwareAddr
+	seenThisCycle bool
+	missedCycles  int
+}
+
+// HostCache deduplicates repeated ARP discoveries across scan cycles. It
+// reports a host only on first sight or MAC change, and considers a host
+// gone after it misses missCycles consecutive cycles.
+type HostCache struct {
+	missCycles int
+
+	mu    sync.Mutex
+	hosts map[string]*cachedHost
+}
+
+// NewHostCache returns a HostCache that evicts hosts after missCycles
+// consecutive scan cycles without a sighting.
+func NewHostCache(missCycles int) *HostCache {
+	return &HostCache{
+		missCycles: missCycles,
+		hosts:      make(map[string]*cachedHost),
+	}
+}
+
+// Observe records a sighting of ip/mac and reports whether this is new
+// information worth a HostDiscovered event (first sight, or a MAC change).
+func (c *HostCache) Observe(ip net.IP, mac net.HardwareAddr) bool {
+	key := ip.String()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	host, ok := c.hosts[key]
+	if !ok {
+		c.hosts[key] = &cachedHost{mac: mac, seenThisCycle: true}
+		return true
+	}
+
+	host.seenThisCycle = true
+	host.missedCycles = 0
+	if bytes.Equal(host.mac, mac) {
+		return false
+	}
+	host.mac = mac
+	return true
+}
+
+// LostHost describes a host that was evicted by Cycle.
+type LostHost struct {
+	IP  net.IP
+	MAC net.HardwareAddr
+}
+
+// Cycle closes out a scan cycle: hosts not observed since the last call get
+// a missed cycle counted against them, and any host at or beyond missCycles
+// is evicted and returned.
+func (c *HostCache) Cycle() []LostHost {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var lost []LostHost
+	for key, host := range c.hosts {
+		if host.seenThisCycle {
+			host.seenThisCycle = false
+			continue
+		}
+
+		host.missedCycles++
+		if host.missedCycles >= c.missCycles {
+			lost = append(lost, LostHost{IP: net.ParseIP(key), MAC: host.mac})
+			delete(c.hosts, key)
+		}
+	}
+	return lost
+}
+
+// Count returns the number of hosts currently tracked as present.
+func (c *HostCache) Count() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.hosts)
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"net"
+	"testing"
+)
+
+func TestHostCacheObserve(t *testing.T) {
+	cache := NewHostCache(2)
+	ip := net.ParseIP("10.0.0.1")
+	mac1 := net.HardwareAddr{0, 1, 2, 3, 4, 5}
+	mac2 := net.HardwareAddr{0, 1, 2, 3, 4, 6}
+
+	if !cache.Observe(ip, mac1) {
+		t.Fatal("first sighting should be reported")
+	}
+	if cache.Observe(ip, mac1) {
+		t.Fatal("repeat sighting with the same MAC should not be reported")
+	}
+	if !cache.Observe(ip, mac2) {
+		t.Fatal("a MAC change should be reported")
+	}
+}
+
+func TestHostCacheCycle(t *testing.T) {
+	cache := NewHostCache(2)
+	ip := net.ParseIP("10.0.0.1")
+	mac := net.HardwareAddr{0, 1, 2, 3, 4, 5}
+	cache.Observe(ip, mac)
+
+	if lost := cache.Cycle(); len(lost) != 0 {
+		t.Fatalf("a host seen this cycle should not be lost, got %v", lost)
+	}
+	if lost := cache.Cycle(); len(lost) != 0 {
+		t.Fatalf("a host should survive one missed cycle (missCycles=2), got %v", lost)
+	}
+
+	lost := cache.Cycle()
+	if len(lost) != 1 || !lost[0].IP.Equal(ip) || lost[0].MAC.String() != mac.String() {
+		t.Fatalf("host should be evicted after missCycles consecutive misses, got %v", lost)
+	}
+	if cache.Count() != 0 {
+		t.Fatalf("an evicted host should not be counted, got %d", cache.Count())
+	}
+}
@@ -2,29 +2,133 @@ package main
 
 import (
 	"context"
+	"flag"
+	"fmt"
 	"log"
+	"net"
+	"os"
+	"path/filepath"
 	"sync"
 )
 
+var (
+	portsFlag      = flag.String("ports", "", "comma-separated TCP ports/ranges to SYN scan on discovered hosts, e.g. 22,80,443,8000-8100")
+	reporterFlag   = flag.String("reporter", "log", "how to report discovered hosts: log, ndjson, syslog or prometheus")
+	ndjsonOutFlag  = flag.String("ndjson-out", "", "file to write NDJSON events to when -reporter=ndjson (default stdout)")
+	metricsAddr    = flag.String("metrics-addr", ":9493", "listen address for the Prometheus exporter when -reporter=prometheus")
+	lostAfterFlag  = flag.Int("lost-after", 3, "number of missed scan cycles before a host is reported lost")
+	includeVirtual = flag.Bool("include-virtual", false, "also scan virtual interfaces (tap, bridge, veth, ...)")
+
+	readFlag            = flag.String("read", "", "replay a saved pcap file instead of capturing live traffic")
+	writeFlag           = flag.String("write", "", "tee every sent/received packet to a pcap file derived from this path, one per scanned interface")
+	promiscFlag         = flag.Bool("promisc", true, "capture in promiscuous mode")
+	timestampSourceFlag = flag.String("timestamp-source", "", "hardware timestamp source to request from the NIC, e.g. adapter or adapter_unsynced")
+
+	ppsFlag     = flag.Int("pps", 500, "maximum ARP requests sent per second, per interface")
+	targetsFlag = flag.String("targets", "", "comma-separated CIDRs/IPs to scan instead of each interface's own subnet")
+)
+
 func main() {
+	flag.Parse()
+
+	ports, err := parsePorts(*portsFlag)
+	if err != nil {
+		log.Fatalf("invalid -ports: %s", err)
+	}
+
+	targets, err := parseTargets(*targetsFlag)
+	if err != nil {
+		log.Fatalf("invalid -targets: %s", err)
+	}
+
+	reporter, err := newReporter(*reporterFlag)
+	if err != nil {
+		log.Fatalf("invalid -reporter: %s", err)
+	}
+
+	factory := newHandleFactory()
+
+	cfg := scanConfig{
+		Ports:            ports,
+		Reporter:         reporter,
+		LostAfterCycles:  *lostAfterFlag,
+		HandleFactory:    factory,
+		PacketsPerSecond: *ppsFlag,
+		Targets:          targets,
+	}
+
 	ctx := GetApplicationContext()
-	if err := run(ctx); err != nil {
+	if err := run(ctx, cfg); err != nil {
 		log.Fatal(err)
 	}
 }
 
-func run(ctx context.Context) error {
-	ifaces, err := getInterfaces()
+// newReporter builds the Reporter named by kind, wiring up any flags it needs.
+func newReporter(kind string) (Reporter, error) {
+	switch kind {
+	case "", "log":
+		return newLogReporter(), nil
+	case "ndjson":
+		if *ndjsonOutFlag == "" {
+			return newNDJSONReporter(os.Stdout), nil
+		}
+		return newNDJSONFileReporter(*ndjsonOutFlag)
+	case "syslog":
+		return newSyslogReporter()
+	case "prometheus":
+		return newPrometheusReporter(*metricsAddr), nil
+	default:
+		return nil, fmt.Errorf("unknown reporter %q", kind)
+	}
+}
+
+// newHandleFactory builds the handleFactory described by -read/-write/-promisc/-timestamp-source.
+func newHandleFactory() handleFactory {
+	var factory handleFactory
+	if *readFlag != "" {
+		factory = offlineHandleFactory(*readFlag)
+	} else {
+		factory = liveHandleFactory(*promiscFlag, *timestampSourceFlag)
+	}
+
+	if *writeFlag != "" {
+		factory = teeHandleFactory(factory, *writeFlag)
+	}
+	return factory
+}
+
+func run(ctx context.Context, cfg scanConfig) error {
+	wg := &sync.WaitGroup{}
+
+	if *readFlag != "" {
+		// Replay drives a single synthetic pseudo-interface instead of every
+		// physical NIC on this host - otherwise we'd replay the same canned
+		// capture once per real NIC concurrently, and every discovered host
+		// would be attributed to whichever one happened to win the race.
+		cfg.Offline = true
+		wg.Add(1)
+		go scanIface(ctx, wg, replayInterface(*readFlag), cfg)
+		wg.Wait()
+		return nil
+	}
+
+	ifaces, err := getInterfaces(*includeVirtual)
 	if err != nil {
 		return err
 	}
 
-	wg := &sync.WaitGroup{}
 	for _, iface := range ifaces {
 		wg.Add(1)
-		go scanIface(ctx, wg, iface)
+		go scanIface(ctx, wg, iface, cfg)
 	}
 
 	wg.Wait()
 	return nil
 }
+
+// replayInterface builds the placeholder net.Interface scanIface uses when
+// replaying a saved capture via -read, named after the capture file so
+// discovered hosts are reported against something recognizable.
+func replayInterface(path string) net.Interface {
+	return net.Interface{Name: "replay:" + filepath.Base(path)}
+}
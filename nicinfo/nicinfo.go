@@ -0,0 +1,26 @@
+// Package nicinfo tells physical network interfaces apart from virtual ones
+// (tunnels, bridges, veth/tap pairs, hypervisor NICs) across platforms, since
+// each OS exposes that distinction through a different API.
+package nicinfo
+
+import "net"
+
+// Info describes what's known about the hardware behind a net.Interface.
+type Info struct {
+	// IsPhysical reports whether iface is backed by real hardware.
+	IsPhysical bool
+	// IsVirtual reports whether iface is a virtual/software device (tap,
+	// veth, bridge, tunnel, hypervisor NIC, ...).
+	IsVirtual bool
+	// Driver is the kernel/vendor driver name backing the interface, when known.
+	Driver string
+	// MediaType describes the link media (e.g. "Wi-Fi", "Ethernet"), when known.
+	MediaType string
+}
+
+// Get returns what's known about iface on the current platform. When
+// detection is inconclusive, implementations err on the side of IsVirtual so
+// callers don't waste probes on tunnels by default.
+func Get(iface net.Interface) (Info, error) {
+	return get(iface)
+}
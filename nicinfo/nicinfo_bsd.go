@@ -0,0 +1,22 @@
+//go:build freebsd || netbsd || openbsd || dragonfly
+
+package nicinfo
+
+import (
+	"net"
+	"strings"
+)
+
+// virtualPrefixes lists common software interface name prefixes across the
+// BSDs, since unlike Linux there's no single ioctl/sysctl that cleanly
+// distinguishes a real NIC from a software one on every BSD variant.
+var virtualPrefixes = []string{"tun", "tap", "bridge", "lo", "pflog", "enc", "vlan", "lagg", "epair", "vether"}
+
+func get(iface net.Interface) (Info, error) {
+	for _, prefix := range virtualPrefixes {
+		if strings.HasPrefix(iface.Name, prefix) {
+			return Info{IsVirtual: true}, nil
+		}
+	}
+	return Info{IsPhysical: true}, nil
+}
@@ -0,0 +1,47 @@
+//go:build darwin
+
+package nicinfo
+
+import (
+	"bufio"
+	"net"
+	"os/exec"
+	"strings"
+)
+
+func get(iface net.Interface) (Info, error) {
+	ports, err := hardwarePorts()
+	if err != nil {
+		return Info{}, err
+	}
+
+	port, ok := ports[iface.Name]
+	if !ok {
+		// Not reported by networksetup: loopback, utun, bridge, etc.
+		return Info{IsVirtual: true}, nil
+	}
+	return Info{IsPhysical: true, MediaType: port}, nil
+}
+
+// hardwarePorts parses `networksetup -listallhardwareports` into a
+// device name -> hardware port name map, e.g. "en0" -> "Wi-Fi".
+func hardwarePorts() (map[string]string, error) {
+	out, err := exec.Command("networksetup", "-listallhardwareports").Output()
+	if err != nil {
+		return nil, err
+	}
+
+	ports := make(map[string]string)
+	var lastPort string
+	scanner := bufio.NewScanner(strings.NewReader(string(out)))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "Hardware Port: "):
+			lastPort = strings.TrimPrefix(line, "Hardware Port: ")
+		case strings.HasPrefix(line, "Device: "):
+			ports[strings.TrimPrefix(line, "Device: ")] = lastPort
+		}
+	}
+	return ports, scanner.Err()
+}
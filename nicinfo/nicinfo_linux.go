@@ -0,0 +1,39 @@
+//go:build linux
+
+package nicinfo
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	sysfsPath            = "/sys/class/net"
+	sysfsVirtualDevsPath = "/sys/devices/virtual"
+)
+
+func get(iface net.Interface) (Info, error) {
+	// dirty workaround to check if NIC is not virtual.
+	//
+	// This also can be done with ioctl and flag IIF_LOWER_UP check but this is a faster way.
+	dstPath, err := os.Readlink(filepath.Join(sysfsPath, iface.Name))
+	if err != nil {
+		return Info{}, err
+	}
+
+	absPath := filepath.Clean(filepath.Join(sysfsPath, dstPath))
+	isVirtual := strings.HasPrefix(absPath, sysfsVirtualDevsPath)
+
+	var driver string
+	if link, err := os.Readlink(filepath.Join(sysfsPath, iface.Name, "device", "driver")); err == nil {
+		driver = filepath.Base(link)
+	}
+
+	return Info{
+		IsPhysical: !isVirtual,
+		IsVirtual:  isVirtual,
+		Driver:     driver,
+	}, nil
+}
@@ -0,0 +1,69 @@
+//go:build windows
+
+package nicinfo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os/exec"
+)
+
+// win32NetworkAdapter mirrors the WMI Win32_NetworkAdapter fields we care about.
+type win32NetworkAdapter struct {
+	NetConnectionID string
+	PhysicalAdapter bool
+	ServiceName     string
+}
+
+func get(iface net.Interface) (Info, error) {
+	adapters, err := queryAdapters()
+	if err != nil {
+		return Info{}, err
+	}
+
+	for _, a := range adapters {
+		if a.NetConnectionID == iface.Name {
+			return Info{
+				IsPhysical: a.PhysicalAdapter,
+				IsVirtual:  !a.PhysicalAdapter,
+				Driver:     a.ServiceName,
+			}, nil
+		}
+	}
+	// Not reported by WMI (e.g. a WAN miniport or loopback): treat as virtual.
+	return Info{IsVirtual: true}, nil
+}
+
+// queryAdapters shells out to PowerShell for Win32_NetworkAdapter rows, since
+// that avoids a direct WMI/COM binding for a handful of fields.
+func queryAdapters() ([]win32NetworkAdapter, error) {
+	cmd := exec.Command("powershell", "-NoProfile", "-Command",
+		"Get-CimInstance Win32_NetworkAdapter | Select-Object NetConnectionID,PhysicalAdapter,ServiceName | ConvertTo-Json")
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("nicinfo: querying WMI: %w", err)
+	}
+
+	trimmed := bytes.TrimSpace(out)
+	if len(trimmed) == 0 {
+		return nil, nil
+	}
+
+	// ConvertTo-Json emits a single object rather than an array when there's
+	// only one result.
+	if trimmed[0] == '{' {
+		var single win32NetworkAdapter
+		if err := json.Unmarshal(trimmed, &single); err != nil {
+			return nil, err
+		}
+		return []win32NetworkAdapter{single}, nil
+	}
+
+	var adapters []win32NetworkAdapter
+	if err := json.Unmarshal(trimmed, &adapters); err != nil {
+		return nil, err
+	}
+	return adapters, nil
+}
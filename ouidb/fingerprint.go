@@ -0,0 +1,58 @@
+package ouidb
+
+import "net"
+
+// virtualOUIs maps OUIs issued to well-known hypervisors/virtualization
+// products. Docker is handled separately below since it hands out the
+// second-level 02:42:* prefix rather than a registered OUI.
+var virtualOUIs = map[uint32]string{
+	0x000c29: "VMware",
+	0x005056: "VMware",
+	0x080027: "VirtualBox",
+	0x00163e: "Xen",
+	0x00155d: "Hyper-V",
+}
+
+// Flags describes what a MAC's administrative bits and known vendor OUIs
+// suggest about whether it belongs to real hardware or a virtual NIC.
+type Flags struct {
+	// LocallyAdministered reports whether the U/L bit is set, meaning the
+	// address was assigned by software rather than burned in by the
+	// manufacturer.
+	LocallyAdministered bool
+	// Multicast reports whether the I/G bit is set; a unicast NIC's address
+	// should never have this set.
+	Multicast bool
+	// LikelyVirtual reports whether the OUI (or, for Docker, the first two
+	// octets) matches a vendor known to hand out MACs to virtual NICs.
+	LikelyVirtual bool
+	// VirtualVendor names the hypervisor/runtime the OUI belongs to, when
+	// LikelyVirtual is true.
+	VirtualVendor string
+}
+
+// Inspect classifies mac by its administrative bits and known virtual vendor
+// OUIs. It doesn't consult the embedded IEEE database, so it works even for
+// OUIs Lookup doesn't recognize.
+func Inspect(mac net.HardwareAddr) Flags {
+	var f Flags
+	if len(mac) == 0 {
+		return f
+	}
+	f.LocallyAdministered = mac[0]&0x02 != 0
+	f.Multicast = mac[0]&0x01 != 0
+
+	if len(mac) >= 2 && mac[0] == 0x02 && mac[1] == 0x42 {
+		f.LikelyVirtual = true
+		f.VirtualVendor = "Docker"
+		return f
+	}
+
+	if oui, ok := ouiOf(mac); ok {
+		if vendor, ok := virtualOUIs[oui]; ok {
+			f.LikelyVirtual = true
+			f.VirtualVendor = vendor
+		}
+	}
+	return f
+}
@@ -0,0 +1,60 @@
+// Command gen-ouidb rebuilds ouidb's embedded database from the public IEEE
+// OUI registry. It's not part of the arpscan binary; run it via
+// `go generate ./ouidb/...` from the repo root to refresh data/oui.db.gz.
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+)
+
+const ouiRegistryURL = "https://standards-oui.ieee.org/oui/oui.txt"
+
+var outPath = flag.String("out", "data/oui.db.gz", "path to write the compressed OUI database to")
+
+// lineRE matches vendor lines in oui.txt, which look like:
+//
+//	AC-DE-48   (hex)		Private
+var lineRE = regexp.MustCompile(`^([0-9A-Fa-f]{2})-([0-9A-Fa-f]{2})-([0-9A-Fa-f]{2})\s+\(hex\)\s+(.+)$`)
+
+func main() {
+	flag.Parse()
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, "gen-ouidb:", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	resp, err := http.Get(ouiRegistryURL)
+	if err != nil {
+		return fmt.Errorf("fetching %s: %w", ouiRegistryURL, err)
+	}
+	defer resp.Body.Close()
+
+	out, err := os.Create(*outPath)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	gz := gzip.NewWriter(out)
+	defer gz.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		m := lineRE.FindStringSubmatch(scanner.Text())
+		if m == nil {
+			continue
+		}
+		if _, err := fmt.Fprintf(gz, "%s%s%s\t%s\n", m[1], m[2], m[3], m[4]); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
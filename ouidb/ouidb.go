@@ -0,0 +1,70 @@
+// Package ouidb looks up the IEEE-registered vendor for a MAC address from a
+// database of OUIs (Organizationally Unique Identifiers) embedded at build
+// time, and flags the administrative bits and well-known vendor OUIs that
+// usually mean a MAC belongs to a virtual NIC rather than real hardware.
+//
+// The embedded data/oui.db.gz shipped in this repo is a curated subset of
+// common consumer/enterprise and virtualization vendors, not a full dump of
+// the ~30k-entry IEEE registry - ouidb/gen's generator was written to
+// produce one, but fetching standards-oui.ieee.org wasn't possible in the
+// environment that last refreshed this file. Lookup will miss plenty of
+// real hardware until someone runs `go generate ./ouidb/...` with network
+// access to the registry.
+package ouidb
+
+//go:generate go run ./gen -out data/oui.db.gz
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	_ "embed"
+	"net"
+	"strconv"
+	"strings"
+)
+
+//go:embed data/oui.db.gz
+var compressedDB []byte
+
+var db map[uint32]string
+
+func init() {
+	gz, err := gzip.NewReader(bytes.NewReader(compressedDB))
+	if err != nil {
+		panic("ouidb: corrupt embedded database: " + err.Error())
+	}
+	defer gz.Close()
+
+	db = make(map[uint32]string)
+	scanner := bufio.NewScanner(gz)
+	for scanner.Scan() {
+		oui, vendor, ok := strings.Cut(scanner.Text(), "\t")
+		if !ok {
+			continue
+		}
+		key, err := strconv.ParseUint(oui, 16, 32)
+		if err != nil {
+			continue
+		}
+		db[uint32(key)] = vendor
+	}
+}
+
+// Lookup returns the IEEE-registered vendor for mac's OUI (its first 3
+// bytes), if known.
+func Lookup(mac net.HardwareAddr) (vendor string, ok bool) {
+	oui, ok := ouiOf(mac)
+	if !ok {
+		return "", false
+	}
+	vendor, ok = db[oui]
+	return vendor, ok
+}
+
+func ouiOf(mac net.HardwareAddr) (uint32, bool) {
+	if len(mac) < 3 {
+		return 0, false
+	}
+	return uint32(mac[0])<<16 | uint32(mac[1])<<8 | uint32(mac[2]), true
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// tokenBucket is a simple packets-per-second limiter: it hands out one token
+// every 1/rate and blocks Wait callers until the next one is available.
+type tokenBucket struct {
+	ticker *time.Ticker
+}
+
+// newTokenBucket returns a tokenBucket that allows up to pps packets per
+// second. pps <= 0 is treated as unlimited.
+func newTokenBucket(pps int) *tokenBucket {
+	if pps <= 0 {
+		return &tokenBucket{}
+	}
+	return &tokenBucket{ticker: time.NewTicker(time.Second / time.Duration(pps))}
+}
+
+// Wait blocks until a token is available or ctx is cancelled.
+func (b *tokenBucket) Wait(ctx context.Context) error {
+	if b.ticker == nil {
+		return nil
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-b.ticker.C:
+		return nil
+	}
+}
+
+func (b *tokenBucket) Stop() {
+	if b.ticker != nil {
+		b.ticker.Stop()
+	}
+}
+
+// jitter returns a random duration in [0, max), used to desynchronize the
+// send loops of multiple interfaces scanned in parallel.
+func jitter(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(max)))
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"log"
+	"net"
+	"time"
+
+	"github.com/x1unix/arpscan/ouidb"
+)
+
+// Reporter receives scan events from the ARP scanner so scanIface itself
+// stays agnostic of how results are surfaced (console, NDJSON, syslog,
+// Prometheus, ...).
+type Reporter interface {
+	// HostDiscovered is called the first time a host is seen on an interface,
+	// or again if its MAC address changes.
+	HostDiscovered(iface string, ip net.IP, mac net.HardwareAddr, ts time.Time)
+
+	// HostLost is called once a previously discovered host has missed enough
+	// consecutive scan cycles to be considered gone.
+	HostLost(iface string, ip net.IP, mac net.HardwareAddr, ts time.Time)
+
+	// ScanCycleComplete is called after each ARP ping interval, reporting how
+	// many hosts are currently known on the interface.
+	ScanCycleComplete(iface string, ts time.Time, hostCount int)
+
+	// ARPRequestSent is called for every ARP request packet written to the wire.
+	ARPRequestSent(iface string)
+
+	// ARPReplyReceived is called for every ARP reply packet read off the wire,
+	// regardless of whether it results in a HostDiscovered event.
+	ARPReplyReceived(iface string)
+}
+
+// logReporter writes human-readable lines to the standard logger. It's the
+// default reporter and preserves arpscan's original console output.
+type logReporter struct{}
+
+func newLogReporter() Reporter {
+	return logReporter{}
+}
+
+func (logReporter) HostDiscovered(iface string, ip net.IP, mac net.HardwareAddr, _ time.Time) {
+	log.Printf(":: [%s] Found %v (%v)%s", iface, ip, mac, vendorSuffix(mac))
+}
+
+func (logReporter) HostLost(iface string, ip net.IP, mac net.HardwareAddr, _ time.Time) {
+	log.Printf(":: [%s] Lost %v (%v)", iface, ip, mac)
+}
+
+func (logReporter) ScanCycleComplete(string, time.Time, int) {}
+
+func (logReporter) ARPRequestSent(string) {}
+
+func (logReporter) ARPReplyReceived(string) {}
+
+// vendorSuffix formats a short, human-readable annotation of what's known
+// about mac's vendor/virtualization status, for appending to a log line.
+func vendorSuffix(mac net.HardwareAddr) string {
+	flags := ouidb.Inspect(mac)
+	if flags.LikelyVirtual {
+		return " [" + flags.VirtualVendor + ", virtual]"
+	}
+	if vendor, ok := ouidb.Lookup(mac); ok {
+		return " [" + vendor + "]"
+	}
+	if flags.LocallyAdministered {
+		return " [locally administered]"
+	}
+	return ""
+}
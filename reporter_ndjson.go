@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/x1unix/arpscan/ouidb"
+)
+
+// ndjsonEvent is a single newline-delimited JSON record emitted by ndjsonReporter.
+type ndjsonEvent struct {
+	Type                string    `json:"type"`
+	Iface               string    `json:"iface"`
+	IP                  string    `json:"ip,omitempty"`
+	MAC                 string    `json:"mac,omitempty"`
+	Vendor              string    `json:"vendor,omitempty"`
+	Virtual             bool      `json:"virtual,omitempty"`
+	LocallyAdministered bool      `json:"locally_administered,omitempty"`
+	HostCount           int       `json:"host_count,omitempty"`
+	Timestamp           time.Time `json:"ts"`
+}
+
+// ndjsonReporter writes one JSON object per line to an underlying writer,
+// suitable for piping into log shippers such as Filebeat or Vector.
+type ndjsonReporter struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+func newNDJSONReporter(w io.Writer) Reporter {
+	return &ndjsonReporter{enc: json.NewEncoder(w)}
+}
+
+// newNDJSONFileReporter opens (creating/appending) path and returns a
+// Reporter that writes NDJSON events to it.
+func newNDJSONFileReporter(path string) (Reporter, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return newNDJSONReporter(f), nil
+}
+
+func (r *ndjsonReporter) write(ev ndjsonEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.enc.Encode(ev); err != nil {
+		log.Printf(":: WARN - failed to write NDJSON event: %s\n", err)
+	}
+}
+
+func (r *ndjsonReporter) HostDiscovered(iface string, ip net.IP, mac net.HardwareAddr, ts time.Time) {
+	flags := ouidb.Inspect(mac)
+	vendor, _ := ouidb.Lookup(mac)
+	if flags.LikelyVirtual {
+		vendor = flags.VirtualVendor
+	}
+
+	r.write(ndjsonEvent{
+		Type:                "host_discovered",
+		Iface:               iface,
+		IP:                  ip.String(),
+		MAC:                 mac.String(),
+		Vendor:              vendor,
+		Virtual:             flags.LikelyVirtual,
+		LocallyAdministered: flags.LocallyAdministered,
+		Timestamp:           ts,
+	})
+}
+
+func (r *ndjsonReporter) HostLost(iface string, ip net.IP, mac net.HardwareAddr, ts time.Time) {
+	r.write(ndjsonEvent{Type: "host_lost", Iface: iface, IP: ip.String(), MAC: mac.String(), Timestamp: ts})
+}
+
+func (r *ndjsonReporter) ScanCycleComplete(iface string, ts time.Time, hostCount int) {
+	r.write(ndjsonEvent{Type: "scan_cycle_complete", Iface: iface, HostCount: hostCount, Timestamp: ts})
+}
+
+func (r *ndjsonReporter) ARPRequestSent(string) {}
+
+func (r *ndjsonReporter) ARPReplyReceived(string) {}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+)
+
+func decodeNDJSONLine(t *testing.T, buf *bytes.Buffer) ndjsonEvent {
+	t.Helper()
+
+	var ev ndjsonEvent
+	if err := json.NewDecoder(buf).Decode(&ev); err != nil {
+		t.Fatalf("decode NDJSON event: %s", err)
+	}
+	return ev
+}
+
+func TestNDJSONReporterHostDiscovered(t *testing.T) {
+	buf := &bytes.Buffer{}
+	r := newNDJSONReporter(buf)
+	ts := time.Unix(1700000000, 0).UTC()
+	ip := net.IPv4(192, 168, 1, 50)
+	mac := net.HardwareAddr{0x00, 0x0c, 0x29, 0x01, 0x02, 0x03} // VMware OUI
+
+	r.HostDiscovered("eth0", ip, mac, ts)
+
+	ev := decodeNDJSONLine(t, buf)
+	if ev.Type != "host_discovered" {
+		t.Errorf("Type = %q, want host_discovered", ev.Type)
+	}
+	if ev.Iface != "eth0" {
+		t.Errorf("Iface = %q, want eth0", ev.Iface)
+	}
+	if ev.IP != ip.String() {
+		t.Errorf("IP = %q, want %s", ev.IP, ip)
+	}
+	if ev.MAC != mac.String() {
+		t.Errorf("MAC = %q, want %s", ev.MAC, mac)
+	}
+	if !ev.Virtual {
+		t.Error("expected Virtual to be true for a VMware OUI")
+	}
+	if !ev.Timestamp.Equal(ts) {
+		t.Errorf("Timestamp = %s, want %s", ev.Timestamp, ts)
+	}
+}
+
+func TestNDJSONReporterHostLost(t *testing.T) {
+	buf := &bytes.Buffer{}
+	r := newNDJSONReporter(buf)
+	ts := time.Unix(1700000001, 0).UTC()
+	ip := net.IPv4(192, 168, 1, 50)
+	mac := net.HardwareAddr{0, 1, 2, 3, 4, 5}
+
+	r.HostLost("eth0", ip, mac, ts)
+
+	ev := decodeNDJSONLine(t, buf)
+	if ev.Type != "host_lost" {
+		t.Errorf("Type = %q, want host_lost", ev.Type)
+	}
+	if ev.IP != ip.String() || ev.MAC != mac.String() {
+		t.Errorf("IP/MAC = %s/%s, want %s/%s", ev.IP, ev.MAC, ip, mac)
+	}
+}
+
+func TestNDJSONReporterScanCycleComplete(t *testing.T) {
+	buf := &bytes.Buffer{}
+	r := newNDJSONReporter(buf)
+	ts := time.Unix(1700000002, 0).UTC()
+
+	r.ScanCycleComplete("eth0", ts, 3)
+
+	ev := decodeNDJSONLine(t, buf)
+	if ev.Type != "scan_cycle_complete" {
+		t.Errorf("Type = %q, want scan_cycle_complete", ev.Type)
+	}
+	if ev.HostCount != 3 {
+		t.Errorf("HostCount = %d, want 3", ev.HostCount)
+	}
+	if ev.IP != "" || ev.MAC != "" {
+		t.Errorf("expected no IP/MAC on a scan_cycle_complete event, got %q/%q", ev.IP, ev.MAC)
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	hostsSeen = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "arpscan",
+		Name:      "hosts_seen",
+		Help:      "Number of hosts currently seen on an interface.",
+	}, []string{"iface"})
+
+	arpRequestsSentTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "arpscan",
+		Name:      "arp_requests_sent_total",
+		Help:      "Total number of ARP request packets sent.",
+	}, []string{"iface"})
+
+	arpRepliesReceivedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "arpscan",
+		Name:      "arp_replies_received_total",
+		Help:      "Total number of ARP reply packets received.",
+	}, []string{"iface"})
+)
+
+// prometheusReporter exposes discovery state as Prometheus metrics on addr.
+type prometheusReporter struct{}
+
+// newPrometheusReporter starts an HTTP server on addr serving /metrics and
+// returns a Reporter that feeds it.
+func newPrometheusReporter(addr string) Reporter {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+
+	go func() {
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			log.Printf("ERROR: prometheus exporter stopped: %s\n", err)
+		}
+	}()
+
+	return prometheusReporter{}
+}
+
+func (prometheusReporter) HostDiscovered(string, net.IP, net.HardwareAddr, time.Time) {}
+
+func (prometheusReporter) HostLost(string, net.IP, net.HardwareAddr, time.Time) {}
+
+func (prometheusReporter) ScanCycleComplete(iface string, _ time.Time, hostCount int) {
+	hostsSeen.WithLabelValues(iface).Set(float64(hostCount))
+}
+
+func (prometheusReporter) ARPRequestSent(iface string) {
+	arpRequestsSentTotal.WithLabelValues(iface).Inc()
+}
+
+func (prometheusReporter) ARPReplyReceived(iface string) {
+	arpRepliesReceivedTotal.WithLabelValues(iface).Inc()
+}
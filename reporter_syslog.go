@@ -0,0 +1,40 @@
+//go:build !windows
+
+package main
+
+import (
+	"fmt"
+	"log/syslog"
+	"net"
+	"time"
+)
+
+// syslogReporter forwards discovery events to the local syslog daemon. Not
+// available on Windows, which has no log/syslog support.
+type syslogReporter struct {
+	w *syslog.Writer
+}
+
+func newSyslogReporter() (Reporter, error) {
+	w, err := syslog.New(syslog.LOG_INFO|syslog.LOG_DAEMON, "arpscan")
+	if err != nil {
+		return nil, err
+	}
+	return &syslogReporter{w: w}, nil
+}
+
+func (r *syslogReporter) HostDiscovered(iface string, ip net.IP, mac net.HardwareAddr, _ time.Time) {
+	_ = r.w.Info(fmt.Sprintf("[%s] host discovered %s (%s)", iface, ip, mac))
+}
+
+func (r *syslogReporter) HostLost(iface string, ip net.IP, mac net.HardwareAddr, _ time.Time) {
+	_ = r.w.Info(fmt.Sprintf("[%s] host lost %s (%s)", iface, ip, mac))
+}
+
+func (r *syslogReporter) ScanCycleComplete(iface string, _ time.Time, hostCount int) {
+	_ = r.w.Debug(fmt.Sprintf("[%s] scan cycle complete, %d host(s) known", iface, hostCount))
+}
+
+func (r *syslogReporter) ARPRequestSent(string) {}
+
+func (r *syslogReporter) ARPReplyReceived(string) {}
@@ -0,0 +1,9 @@
+//go:build windows
+
+package main
+
+import "errors"
+
+func newSyslogReporter() (Reporter, error) {
+	return nil, errors.New("syslog reporter is not supported on windows")
+}
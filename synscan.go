@@ -0,0 +1,311 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/routing"
+)
+
+const (
+	synProbeTimeout   = 2 * time.Second
+	synSweepInterval  = 500 * time.Millisecond
+	arpResolveTimeout = 2 * time.Second
+)
+
+// PortState is the classification of a probed TCP port.
+type PortState int
+
+const (
+	PortOpen PortState = iota
+	PortClosed
+	PortFiltered
+)
+
+func (s PortState) String() string {
+	switch s {
+	case PortOpen:
+		return "open"
+	case PortClosed:
+		return "closed"
+	default:
+		return "filtered"
+	}
+}
+
+// probeKey identifies an in-flight SYN probe.
+type probeKey struct {
+	srcIP, dstIP     string
+	srcPort, dstPort uint16
+}
+
+// synScanner performs a TCP SYN sweep against hosts using a shared pcap handle.
+//
+// Results are classified as open (SYN-ACK), closed (RST) or filtered
+// (no response within synProbeTimeout).
+type synScanner struct {
+	handle PacketHandle
+	disp   *packetDispatcher
+	router routing.Router
+
+	mu      sync.Mutex
+	pending map[probeKey]time.Time
+}
+
+func newSynScanner(handle PacketHandle, disp *packetDispatcher, router routing.Router) *synScanner {
+	return &synScanner{
+		handle:  handle,
+		disp:    disp,
+		router:  router,
+		pending: make(map[probeKey]time.Time),
+	}
+}
+
+// ScanHost sends a SYN probe to each of ports on dst. hostMAC, if non-nil, is
+// the MAC address dst was already discovered at via ARP, and is used
+// directly - the whole point of scanning ARP-discovered hosts is that we
+// already know how to reach them on the local segment without flooding the
+// probes as an L2 broadcast. A route through a gateway overrides hostMAC,
+// since in that case dst isn't on this link and the probes need to go to the
+// gateway's MAC instead.
+func (s *synScanner) ScanHost(ctx context.Context, dst net.IP, hostMAC net.HardwareAddr, ports []uint16) error {
+	iface, gw, srcIP, err := s.router.Route(dst)
+	if err != nil {
+		return fmt.Errorf("no route to %s: %w", dst, err)
+	}
+
+	dstMAC := hostMAC
+	if gw != nil {
+		// Off-link target: probes go to the gateway's MAC, not the host's.
+		dstMAC, err = resolveGatewayMAC(ctx, s.handle, s.disp, iface, srcIP, gw)
+		if err != nil {
+			return fmt.Errorf("failed to resolve gateway MAC for %s: %w", dst, err)
+		}
+	} else if dstMAC == nil {
+		// On-link but no known MAC (e.g. a bare -targets entry never seen in
+		// an ARP reply): fall back to broadcast.
+		dstMAC = net.HardwareAddr{0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+	}
+
+	eth := layers.Ethernet{
+		SrcMAC:       iface.HardwareAddr,
+		DstMAC:       dstMAC,
+		EthernetType: layers.EthernetTypeIPv4,
+	}
+	ip := layers.IPv4{
+		Version:  4,
+		TTL:      64,
+		SrcIP:    srcIP,
+		DstIP:    dst,
+		Protocol: layers.IPProtocolTCP,
+	}
+
+	buf := gopacket.NewSerializeBuffer()
+	opts := gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}
+
+	for _, port := range ports {
+		srcPort := uint16(1024 + rand.Intn(64511))
+		tcp := layers.TCP{
+			SrcPort: layers.TCPPort(srcPort),
+			DstPort: layers.TCPPort(port),
+			SYN:     true,
+			Window:  14600,
+			Seq:     rand.Uint32(),
+		}
+		if err := tcp.SetNetworkLayerForChecksum(&ip); err != nil {
+			return err
+		}
+
+		buf.Clear()
+		if err := gopacket.SerializeLayers(buf, opts, &eth, &ip, &tcp); err != nil {
+			log.Printf(":: WARN - %s\n", err)
+			continue
+		}
+
+		key := probeKey{srcIP: srcIP.String(), dstIP: dst.String(), srcPort: srcPort, dstPort: port}
+		s.mu.Lock()
+		s.pending[key] = time.Now()
+		s.mu.Unlock()
+
+		if err := s.handle.WritePacketData(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ReadReplies subscribes to SYN-ACK/RST responses to outstanding probes via
+// disp and reports classification via report, until ctx is cancelled.
+func (s *synScanner) ReadReplies(ctx context.Context, wg *sync.WaitGroup, report func(ip net.IP, port uint16, state PortState)) {
+	defer wg.Done()
+
+	unsubscribe := s.disp.OnTCP(func(ip *layers.IPv4, tcp *layers.TCP) {
+		s.handleTCP(ip, tcp, report)
+	})
+	defer unsubscribe()
+
+	sweep := time.NewTicker(synSweepInterval)
+	defer sweep.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sweep.C:
+			s.reportTimeouts(report)
+		}
+	}
+}
+
+// handleTCP classifies a single TCP/IPv4 packet seen by the interface's
+// packetDispatcher against the set of outstanding probes.
+func (s *synScanner) handleTCP(ip *layers.IPv4, tcp *layers.TCP, report func(ip net.IP, port uint16, state PortState)) {
+	key := probeKey{
+		srcIP:   ip.DstIP.String(),
+		dstIP:   ip.SrcIP.String(),
+		srcPort: uint16(tcp.DstPort),
+		dstPort: uint16(tcp.SrcPort),
+	}
+
+	s.mu.Lock()
+	_, ok := s.pending[key]
+	if ok {
+		delete(s.pending, key)
+	}
+	s.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	switch {
+	case tcp.SYN && tcp.ACK:
+		report(ip.SrcIP, uint16(tcp.SrcPort), PortOpen)
+	case tcp.RST:
+		report(ip.SrcIP, uint16(tcp.SrcPort), PortClosed)
+	}
+}
+
+func (s *synScanner) reportTimeouts(report func(ip net.IP, port uint16, state PortState)) {
+	deadline := time.Now().Add(-synProbeTimeout)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, sentAt := range s.pending {
+		if sentAt.Before(deadline) {
+			delete(s.pending, key)
+			report(net.ParseIP(key.dstIP), key.dstPort, PortFiltered)
+		}
+	}
+}
+
+// resolveGatewayMAC sends an ARP request for gw over iface and blocks until
+// disp reports a matching reply or arpResolveTimeout elapses.
+func resolveGatewayMAC(ctx context.Context, handle PacketHandle, disp *packetDispatcher, iface *net.Interface, srcIP, gw net.IP) (net.HardwareAddr, error) {
+	eth := layers.Ethernet{
+		SrcMAC:       iface.HardwareAddr,
+		DstMAC:       net.HardwareAddr{0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+		EthernetType: layers.EthernetTypeARP,
+	}
+	arp := layers.ARP{
+		AddrType:          layers.LinkTypeEthernet,
+		Protocol:          layers.EthernetTypeIPv4,
+		HwAddressSize:     6,
+		ProtAddressSize:   4,
+		Operation:         layers.ARPRequest,
+		SourceHwAddress:   []byte(iface.HardwareAddr),
+		SourceProtAddress: []byte(srcIP.To4()),
+		DstHwAddress:      []byte{0, 0, 0, 0, 0, 0},
+		DstProtAddress:    []byte(gw.To4()),
+	}
+
+	buf := gopacket.NewSerializeBuffer()
+	opts := gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}
+	if err := gopacket.SerializeLayers(buf, opts, &eth, &arp); err != nil {
+		return nil, err
+	}
+	if err := handle.WritePacketData(buf.Bytes()); err != nil {
+		return nil, err
+	}
+
+	resolveCtx, cancel := context.WithTimeout(ctx, arpResolveTimeout)
+	defer cancel()
+
+	replies := make(chan net.HardwareAddr, 1)
+	unsubscribe := disp.OnARP(func(reply *layers.ARP) {
+		if reply.Operation == layers.ARPReply && net.IP(reply.SourceProtAddress).Equal(gw) {
+			select {
+			case replies <- net.HardwareAddr(reply.SourceHwAddress):
+			default:
+			}
+		}
+	})
+	defer unsubscribe()
+
+	select {
+	case <-resolveCtx.Done():
+		return nil, fmt.Errorf("timed out waiting for ARP reply from %s", gw)
+	case mac := <-replies:
+		return mac, nil
+	}
+}
+
+// parsePorts parses a comma-separated port spec such as "22,80,443,8000-8100"
+// into a sorted, deduplicated list of ports.
+func parsePorts(spec string) ([]uint16, error) {
+	seen := make(map[uint16]struct{})
+	var ports []uint16
+
+	add := func(p int) error {
+		if p < 1 || p > 65535 {
+			return fmt.Errorf("port %d out of range", p)
+		}
+		if _, ok := seen[uint16(p)]; !ok {
+			seen[uint16(p)] = struct{}{}
+			ports = append(ports, uint16(p))
+		}
+		return nil
+	}
+
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if lo, hi, ok := strings.Cut(part, "-"); ok {
+			start, err := strconv.Atoi(lo)
+			if err != nil {
+				return nil, fmt.Errorf("invalid port range %q: %w", part, err)
+			}
+			end, err := strconv.Atoi(hi)
+			if err != nil {
+				return nil, fmt.Errorf("invalid port range %q: %w", part, err)
+			}
+			for p := start; p <= end; p++ {
+				if err := add(p); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+
+		p, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid port %q: %w", part, err)
+		}
+		if err := add(p); err != nil {
+			return nil, err
+		}
+	}
+
+	return ports, nil
+}
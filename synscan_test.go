@@ -0,0 +1,154 @@
+package main
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket/layers"
+)
+
+// reportedPort records one call to a synScanner's report callback.
+type reportedPort struct {
+	ip    net.IP
+	port  uint16
+	state PortState
+}
+
+func TestSynScannerHandleTCP(t *testing.T) {
+	srcIP := net.IPv4(192, 168, 1, 10)
+	dstIP := net.IPv4(192, 168, 1, 50)
+	key := probeKey{srcIP: srcIP.String(), dstIP: dstIP.String(), srcPort: 40000, dstPort: 22}
+
+	tests := []struct {
+		name      string
+		seed      bool
+		tcp       layers.TCP
+		wantState PortState
+		wantCall  bool
+	}{
+		{
+			name:      "SYN-ACK reports open",
+			seed:      true,
+			tcp:       layers.TCP{SrcPort: 22, DstPort: 40000, SYN: true, ACK: true},
+			wantState: PortOpen,
+			wantCall:  true,
+		},
+		{
+			name:      "RST reports closed",
+			seed:      true,
+			tcp:       layers.TCP{SrcPort: 22, DstPort: 40000, RST: true},
+			wantState: PortClosed,
+			wantCall:  true,
+		},
+		{
+			name:     "unrelated probe is ignored",
+			seed:     false,
+			tcp:      layers.TCP{SrcPort: 22, DstPort: 40000, SYN: true, ACK: true},
+			wantCall: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newSynScanner(nil, nil, nil)
+			if tt.seed {
+				s.pending[key] = time.Now()
+			}
+
+			var got []reportedPort
+			ip := &layers.IPv4{SrcIP: dstIP, DstIP: srcIP}
+			s.handleTCP(ip, &tt.tcp, func(ip net.IP, port uint16, state PortState) {
+				got = append(got, reportedPort{ip: ip, port: port, state: state})
+			})
+
+			if !tt.wantCall {
+				if len(got) != 0 {
+					t.Fatalf("expected no report, got %v", got)
+				}
+				if _, ok := s.pending[key]; !ok && tt.seed {
+					t.Fatal("unrelated packet should not consume the pending probe")
+				}
+				return
+			}
+
+			if len(got) != 1 {
+				t.Fatalf("expected one report, got %v", got)
+			}
+			if !got[0].ip.Equal(dstIP) || got[0].port != 22 || got[0].state != tt.wantState {
+				t.Errorf("got %+v, want ip=%s port=22 state=%s", got[0], dstIP, tt.wantState)
+			}
+			if _, ok := s.pending[key]; ok {
+				t.Fatal("handleTCP should remove the probe once matched")
+			}
+		})
+	}
+}
+
+func TestSynScannerReportTimeouts(t *testing.T) {
+	s := newSynScanner(nil, nil, nil)
+	dstIP := net.IPv4(192, 168, 1, 50)
+	expired := probeKey{srcIP: "192.168.1.10", dstIP: dstIP.String(), srcPort: 40000, dstPort: 22}
+	fresh := probeKey{srcIP: "192.168.1.10", dstIP: dstIP.String(), srcPort: 40001, dstPort: 23}
+
+	s.pending[expired] = time.Now().Add(-2 * synProbeTimeout)
+	s.pending[fresh] = time.Now()
+
+	var got []reportedPort
+	s.reportTimeouts(func(ip net.IP, port uint16, state PortState) {
+		got = append(got, reportedPort{ip: ip, port: port, state: state})
+	})
+
+	if len(got) != 1 {
+		t.Fatalf("expected one timeout report, got %v", got)
+	}
+	if !got[0].ip.Equal(dstIP) || got[0].port != 22 || got[0].state != PortFiltered {
+		t.Errorf("got %+v, want ip=%s port=22 state=filtered", got[0], dstIP)
+	}
+	if _, ok := s.pending[expired]; ok {
+		t.Error("expired probe should be removed from pending")
+	}
+	if _, ok := s.pending[fresh]; !ok {
+		t.Error("fresh probe should remain pending")
+	}
+}
+
+func TestParsePorts(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    []uint16
+		wantErr bool
+	}{
+		{spec: "", want: nil},
+		{spec: "22", want: []uint16{22}},
+		{spec: "22,80,443", want: []uint16{22, 80, 443}},
+		{spec: "8000-8002", want: []uint16{8000, 8001, 8002}},
+		{spec: "22,22,80", want: []uint16{22, 80}},
+		{spec: "0", wantErr: true},
+		{spec: "70000", wantErr: true},
+		{spec: "abc", wantErr: true},
+		{spec: "80-abc", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parsePorts(tt.spec)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parsePorts(%q): expected error, got none", tt.spec)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parsePorts(%q): unexpected error: %s", tt.spec, err)
+			continue
+		}
+		if len(got) != len(tt.want) {
+			t.Fatalf("parsePorts(%q) = %v, want %v", tt.spec, got, tt.want)
+		}
+		for i := range tt.want {
+			if got[i] != tt.want[i] {
+				t.Errorf("parsePorts(%q)[%d] = %d, want %d", tt.spec, i, got[i], tt.want[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// hostAddrs streams every usable host address in n to the returned channel,
+// skipping the network and broadcast addresses on anything larger than a
+// /31, closing the channel when done or when ctx is cancelled. Unlike
+// building the whole address list up front, this lets callers pace or abort
+// a scan of a /16 or larger without pre-allocating millions of net.IPs.
+func hostAddrs(ctx context.Context, n *net.IPNet) <-chan net.IP {
+	out := make(chan net.IP)
+
+	go func() {
+		defer close(out)
+
+		num := binary.BigEndian.Uint32(n.IP.To4())
+		mask := binary.BigEndian.Uint32(n.Mask)
+		network := num & mask
+		broadcast := network | ^mask
+
+		start, end := network, broadcast
+		if mask != 0xffffffff && broadcast > network+1 {
+			start++
+			end--
+		}
+
+		for ip := start; ; ip++ {
+			var buf [4]byte
+			binary.BigEndian.PutUint32(buf[:], ip)
+			select {
+			case <-ctx.Done():
+				return
+			case out <- net.IP(buf[:]).To4():
+			}
+			if ip == end {
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// parseTargets parses a comma-separated list of CIDRs and/or bare IPv4
+// addresses (treated as /32) into the subnets writeARP should enumerate,
+// letting users scan ranges other than an interface's own subnet.
+func parseTargets(spec string) ([]*net.IPNet, error) {
+	var targets []*net.IPNet
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		if !strings.Contains(part, "/") {
+			ip := net.ParseIP(part).To4()
+			if ip == nil {
+				return nil, fmt.Errorf("invalid target %q: not an IPv4 address", part)
+			}
+			part = fmt.Sprintf("%s/32", ip)
+		}
+
+		_, ipNet, err := net.ParseCIDR(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid target %q: %w", part, err)
+		}
+		targets = append(targets, ipNet)
+	}
+	return targets, nil
+}
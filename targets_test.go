@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+)
+
+func TestParseTargets(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    []string
+		wantErr bool
+	}{
+		{spec: "", want: nil},
+		{spec: "10.0.0.1", want: []string{"10.0.0.1/32"}},
+		{spec: "10.0.0.0/30,192.168.1.5", want: []string{"10.0.0.0/30", "192.168.1.5/32"}},
+		{spec: "not-an-ip", wantErr: true},
+		{spec: "10.0.0.0/99", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseTargets(tt.spec)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseTargets(%q): expected error, got none", tt.spec)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseTargets(%q): unexpected error: %s", tt.spec, err)
+			continue
+		}
+		if len(got) != len(tt.want) {
+			t.Fatalf("parseTargets(%q) = %v, want %v", tt.spec, got, tt.want)
+		}
+		for i, n := range got {
+			if n.String() != tt.want[i] {
+				t.Errorf("parseTargets(%q)[%d] = %s, want %s", tt.spec, i, n, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestHostAddrs(t *testing.T) {
+	_, n, err := net.ParseCIDR("192.168.1.0/30")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for ip := range hostAddrs(context.Background(), n) {
+		got = append(got, ip.String())
+	}
+
+	want := []string{"192.168.1.1", "192.168.1.2"}
+	if len(got) != len(want) {
+		t.Fatalf("hostAddrs(%s) = %v, want %v", n, got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("hostAddrs(%s)[%d] = %s, want %s", n, i, got[i], want[i])
+		}
+	}
+}
+
+// TestHostAddrsPointToPoint covers the /31 case, which has no network or
+// broadcast address to exclude.
+func TestHostAddrsPointToPoint(t *testing.T) {
+	_, n, err := net.ParseCIDR("192.168.1.0/31")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for ip := range hostAddrs(context.Background(), n) {
+		got = append(got, ip.String())
+	}
+
+	want := []string{"192.168.1.0", "192.168.1.1"}
+	if len(got) != len(want) {
+		t.Fatalf("hostAddrs(%s) = %v, want %v", n, got, want)
+	}
+}
+
+// TestHostAddrsCancel makes sure the producer goroutine exits promptly once
+// ctx is cancelled instead of blocking forever trying to send into a channel
+// nobody's reading anymore.
+func TestHostAddrsCancel(t *testing.T) {
+	_, n, err := net.ParseCIDR("10.0.0.0/8")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	out := hostAddrs(ctx, n)
+	<-out
+	cancel()
+
+	for range out {
+	}
+}